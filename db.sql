@@ -3,13 +3,17 @@ EXTENSION IF NOT EXISTS pgcrypto;
 
 CREATE TABLE artists
 (
-    id         UUID      NOT NULL DEFAULT gen_random_uuid() UNIQUE,
-    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMP NULL,
-    deleted_at TIMESTAMP NULL,
+    id           UUID      NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP NULL,
+    deleted_at   TIMESTAMP NULL,
 
-    name       TEXT,
-    nickname   TEXT
+    name         TEXT,
+    nickname     TEXT,
+    genres       TEXT[]    NULL,
+    homepage_url TEXT      NULL,
+    created_by   TEXT      NULL,
+    updated_by   TEXT      NULL
 );
 
 CREATE TABLE contacts
@@ -23,6 +27,11 @@ CREATE TABLE contacts
     phone           INTEGER,
     radio_frequency REAL,
     last_call       TIMESTAMP,
+    website         TEXT      NULL,
+    fax             TEXT      NULL,
+    preferred       TEXT      NULL,
+    address_street  TEXT      NULL,
+    address_city    TEXT      NULL,
     on_social_media BOOLEAN,
     artist_id       UUID      NOT NULL
 );
@@ -36,5 +45,87 @@ CREATE TABLE albums
 
     name       TEXT,
     year       INT,
+    decade     INT GENERATED ALWAYS AS (year / 10 * 10) STORED,
+    details    JSONB     NULL,
+    credits    JSONB     NULL,
+    musicians  JSONB     NULL,
+    charts     BIGINT[]  NULL,
+    guests     UUID[]    NULL,
+    price      BIGINT    NULL,
+    length     BIGINT    NULL,
+    catalog_id UUID      NULL,
+    label_id   BIGINT    NULL,
     artist_id  UUID      NOT NULL
 );
+
+CREATE TABLE labels
+(
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NULL,
+    deleted_at TIMESTAMP NULL,
+
+    name       TEXT
+);
+
+CREATE TABLE genres
+(
+    id   TEXT PRIMARY KEY,
+    name TEXT
+);
+
+CREATE TABLE artist_labels
+(
+    artist_id  UUID      NOT NULL,
+    label_id   BIGINT    NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NULL,
+    deleted_at TIMESTAMP NULL,
+
+    role       TEXT,
+    PRIMARY KEY (artist_id, label_id)
+);
+
+CREATE TABLE tracks
+(
+    id          UUID      NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    inserted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    modified_at TIMESTAMP NULL,
+    archived_at TIMESTAMP NULL,
+
+    name        TEXT,
+    number      INT,
+    album_id    UUID      NOT NULL
+);
+
+CREATE TABLE album_genres
+(
+    album_id UUID NOT NULL,
+    genre_id TEXT NOT NULL,
+
+    PRIMARY KEY (album_id, genre_id)
+);
+
+CREATE TABLE comments
+(
+    id         UUID      NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NULL,
+    deleted_at TIMESTAMP NULL,
+
+    body       TEXT,
+    owner_type TEXT      NOT NULL,
+    owner_id   UUID      NOT NULL
+);
+
+CREATE TABLE track_credits
+(
+    id         UUID      NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NULL,
+    deleted_at TIMESTAMP NULL,
+
+    name       TEXT,
+    role       TEXT,
+    track_id   UUID      NOT NULL
+);
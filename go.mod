@@ -1,6 +1,6 @@
 module github.com/derivatan/si_test
 
-go 1.21
+go 1.22
 
 require (
 	github.com/derivatan/si v0.0.0-20251017204737-3d096c248b39
@@ -4,8 +4,10 @@ package integration
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/derivatan/si"
 	"github.com/google/uuid"
@@ -25,9 +27,31 @@ func TestMain(m *testing.M) {
 	si.SetLogger(func(a ...any) {
 		//fmt.Println(a...)
 	})
+	// Fail lazy relation loads that repeat for siblings of the same result, those should use With(...).
+	// Only loads are checked, writes through a relation like Attach and Sync are exempt.
+	si.DetectNPlusOne(failOnNPlusOne)
+	// time.Duration is stored as milliseconds.
+	si.RegisterType(
+		func(d time.Duration) (driver.Value, error) {
+			return d.Milliseconds(), nil
+		},
+		func(src any) (time.Duration, error) {
+			switch v := src.(type) {
+			case nil:
+				return 0, nil
+			case int64:
+				return time.Duration(v) * time.Millisecond, nil
+			}
+			return 0, fmt.Errorf("cannot convert %T to time.Duration", src)
+		},
+	)
 	m.Run()
 }
 
+func failOnNPlusOne(n si.NPlusOne) error {
+	return n
+}
+
 // DB returns a transaction that will rolled back when the test is finished.
 // This is an easy way to reset the database between tests, but will not work if testing transactions.
 func DB(t *testing.T) si.DB {
@@ -44,10 +68,27 @@ func DB(t *testing.T) si.DB {
 	return si.WrapDB(tx)
 }
 
-func Seed[T si.Modeler](tx si.DB, list []T) []uuid.UUID {
-	var result []uuid.UUID
+func Seed[T interface {
+	si.Modeler
+	GetModel() si.Model
+}](tx si.DB, list []T) []uuid.UUID {
+	return SeedKeys[uuid.UUID](tx, list)
+}
+
+// SeedKeys is like Seed, but for any kind of primary key.
+// Models that already have a key, like natural keys, are inserted with that key.
+func SeedKeys[K comparable, T interface {
+	si.Modeler
+	GetModel() si.ModelOf[K]
+}](tx si.DB, list []T) []K {
+	var result []K
 	for _, elem := range list {
-		err := si.Save[T](tx, &elem)
+		var err error
+		if elem.GetModel().ID != nil {
+			err = si.Insert[T](tx, &elem)
+		} else {
+			err = si.Save[T](tx, &elem)
+		}
 		if err != nil {
 			panic(fmt.Errorf("failed to seed '%T': %w", elem, err))
 		}
@@ -55,3 +96,15 @@ func Seed[T si.Modeler](tx si.DB, list []T) []uuid.UUID {
 	}
 	return result
 }
+
+// CountQueries counts the queries that are logged by si until the test is finished.
+func CountQueries(t *testing.T) *int {
+	count := 0
+	si.SetLogger(func(a ...any) {
+		count++
+	})
+	t.Cleanup(func() {
+		si.SetLogger(func(a ...any) {})
+	})
+	return &count
+}
@@ -3,23 +3,80 @@
 package integration
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/derivatan/si"
 	"github.com/google/uuid"
 )
 
+// Auditing is embedded, so its fields are stored in columns without a prefix.
+type Auditing struct {
+	CreatedBy string
+	UpdatedBy string
+}
+
+// Address is stored in columns prefixed with the field's column name, e.g. address_city.
+type Address struct {
+	Street string
+	City   string
+}
+
+// ContactMethod is stored as text, and only the known values are accepted.
+type ContactMethod string
+
+const (
+	ContactMethodNone  ContactMethod = ""
+	ContactMethodEmail ContactMethod = "email"
+	ContactMethodPhone ContactMethod = "phone"
+	ContactMethodRadio ContactMethod = "radio"
+)
+
+func (c ContactMethod) Value() (driver.Value, error) {
+	switch c {
+	case ContactMethodNone:
+		return nil, nil
+	case ContactMethodEmail, ContactMethodPhone, ContactMethodRadio:
+		return string(c), nil
+	}
+	return nil, fmt.Errorf("invalid contact method '%s'", string(c))
+}
+
+func (c *ContactMethod) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*c = ContactMethodNone
+		return nil
+	case string:
+		*c = ContactMethod(v)
+	case []byte:
+		*c = ContactMethod(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ContactMethod", src)
+	}
+	_, err := c.Value()
+	return err
+}
+
 type Contact struct {
 	si.Model
 
 	Email          string
 	Phone          int
-	RadioFrequency float64
-	LastCall       time.Time
+	RadioFrequency sql.Null[float64]
+	LastCall       *time.Time
+	Website        *string
+	Fax            sql.NullString
+	Preferred      ContactMethod
+	Address        Address `si:",embed"`
 	OnSocialMedia  bool
 	ArtistID       uuid.UUID
 
-	artist si.RelationData[Artist]
+	artist   si.RelationData[Artist]
+	comments si.RelationData[Comment]
 }
 
 func (c Contact) GetModel() si.Model {
@@ -33,17 +90,42 @@ func (c Contact) GetTable() string {
 func (c Contact) Artist() *si.Relation[Contact, Artist] {
 	return si.BelongsTo[Contact, Artist](c, "ArtistID", "artist", func(c *Contact) *si.RelationData[Artist] {
 		return &c.artist
+	}).Inverse(func(a *Artist) *si.RelationData[Contact] {
+		return &a.contact
+	})
+}
+
+func (c Contact) Comments() *si.Relation[Contact, Comment] {
+	return si.MorphMany[Contact, Comment](c, "Owner", "comments", func(c *Contact) *si.RelationData[Comment] {
+		return &c.comments
 	})
 }
 
 type Artist struct {
 	si.Model
+	Auditing
 
 	Name     string
 	Nickname string
+	Genres   []string
+	Homepage *string `si:"homepage_url"`
 
-	contact si.RelationData[Contact]
-	albums  si.RelationData[Album]
+	// Popularity is calculated by the application, and is never stored.
+	Popularity int `si:"-"`
+
+	// Aggregates are only read when the query asks for them, e.g. with WithCount("albums").
+	// Min, max and avg are NULL for an artist without albums, while Money reads a NULL sum as zero.
+	AlbumsCount    int               `si:"albums_count,aggregate"`
+	AlbumsSumPrice Money             `si:"albums_sum_price,aggregate"`
+	AlbumsMinYear  sql.Null[int]     `si:"albums_min_year,aggregate"`
+	AlbumsMaxYear  sql.Null[int]     `si:"albums_max_year,aggregate"`
+	AlbumsAvgYear  sql.Null[float64] `si:"albums_avg_year,aggregate"`
+
+	contact  si.RelationData[Contact]
+	albums   si.RelationData[Album]
+	labels   si.RelationData[Label]
+	tracks   si.RelationData[Track]
+	comments si.RelationData[Comment]
 }
 
 func (a Artist) GetModel() si.Model {
@@ -54,26 +136,115 @@ func (a Artist) GetTable() string {
 	return "artists"
 }
 
+// Contact and Albums follow the artist's delete, SOFT_DELETE soft deletes them along with the artist,
+// and hard deletes them when the artist is hard deleted, so no rows are left pointing at a missing artist.
 func (a Artist) Contact() *si.Relation[Artist, Contact] {
 	return si.HasOne[Artist, Contact](a, "ArtistID", "contact", func(a *Artist) *si.RelationData[Contact] {
 		return &a.contact
+	}).OnDelete(si.SOFT_DELETE).Inverse(func(c *Contact) *si.RelationData[Artist] {
+		return &c.artist
 	})
 }
 
 func (a Artist) Albums() *si.Relation[Artist, Album] {
 	return si.HasMany[Artist, Album](a, "ArtistID", "albums", func(a *Artist) *si.RelationData[Album] {
 		return &a.albums
+	}).OnDelete(si.SOFT_DELETE).Inverse(func(a *Album) *si.RelationData[Artist] {
+		return &a.artist
 	})
 }
 
+func (a Artist) Labels() *si.Relation[Artist, Label] {
+	return si.BelongsToMany[Artist, Label](a, "artist_labels", "ArtistID", "LabelID", "labels", func(a *Artist) *si.RelationData[Label] {
+		return &a.labels
+	}).WithPivot("role")
+}
+
+func (a Artist) Tracks() *si.Relation[Artist, Track] {
+	return si.HasManyThrough[Artist, Album, Track](a, a.Albums(), func(m Album) *si.Relation[Album, Track] {
+		return m.Tracks()
+	}, "tracks", func(a *Artist) *si.RelationData[Track] {
+		return &a.tracks
+	})
+}
+
+func (a Artist) Comments() *si.Relation[Artist, Comment] {
+	return si.MorphMany[Artist, Comment](a, "Owner", "comments", func(a *Artist) *si.RelationData[Comment] {
+		return &a.comments
+	})
+}
+
+// Money is an amount in cents.
+type Money int64
+
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	// SUM of a bigint column is NUMERIC, which the driver returns as text.
+	case []byte:
+		return m.Scan(string(v))
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into Money: %w", v, err)
+		}
+		*m = Money(n)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+// CatalogID gets Scan and Value from the embedded uuid.UUID.
+type CatalogID struct {
+	uuid.UUID
+}
+
+type AlbumDetails struct {
+	Label   string   `json:"label"`
+	Formats []string `json:"formats"`
+	Tracks  int      `json:"tracks"`
+}
+
 type Album struct {
 	si.Model
 
-	Name     string
-	Year     int
-	ArtistID uuid.UUID
+	Name      string
+	Year      int
+	Decade    int                 `si:",readonly"`
+	Details   AlbumDetails        `si:",json"`
+	Credits   map[string][]string `si:",json"`
+	Musicians []string            `si:",json"`
+	Charts    []int64
+	Guests    []uuid.UUID
+	Price     Money
+	Length    time.Duration
+	CatalogID *CatalogID
+	LabelID   *int64
+	ArtistID  uuid.UUID
 
-	artist si.RelationData[Artist]
+	artist   si.RelationData[Artist]
+	label    si.RelationData[Label]
+	tracks   si.RelationData[Track]
+	genres   si.RelationData[Genre]
+	contact  si.RelationData[Contact]
+	comments si.RelationData[Comment]
 }
 
 func (a Album) GetModel() si.Model {
@@ -89,3 +260,194 @@ func (a Album) Artist() *si.Relation[Album, Artist] {
 		return &a.artist
 	})
 }
+
+func (a Album) Label() *si.Relation[Album, Label] {
+	return si.BelongsTo[Album, Label](a, "LabelID", "label", func(a *Album) *si.RelationData[Label] {
+		return &a.label
+	})
+}
+
+func (a Album) Tracks() *si.Relation[Album, Track] {
+	// The foreign key is derived from Album by the naming strategy.
+	return si.HasMany[Album, Track](a, si.DeriveForeignKey, "tracks", func(a *Album) *si.RelationData[Track] {
+		return &a.tracks
+	}).OnDelete(si.SOFT_DELETE)
+}
+
+func (a Album) Genres() *si.Relation[Album, Genre] {
+	return si.BelongsToMany[Album, Genre](a, "album_genres", "AlbumID", "GenreID", "genres", func(a *Album) *si.RelationData[Genre] {
+		return &a.genres
+	})
+}
+
+func (a Album) Contact() *si.Relation[Album, Contact] {
+	return si.HasOneThrough[Album, Artist, Contact](a, a.Artist(), func(m Artist) *si.Relation[Artist, Contact] {
+		return m.Contact()
+	}, "contact", func(a *Album) *si.RelationData[Contact] {
+		return &a.contact
+	})
+}
+
+func (a Album) Comments() *si.Relation[Album, Comment] {
+	return si.MorphMany[Album, Comment](a, "Owner", "comments", func(a *Album) *si.RelationData[Comment] {
+		return &a.comments
+	})
+}
+
+// Label has a serial primary key.
+type Label struct {
+	si.ModelOf[int64]
+
+	Name string
+
+	// Pivot holds the artist_labels columns when loaded through Artist.Labels.
+	Pivot si.Pivot `si:"-"`
+
+	albums  si.RelationData[Album]
+	artists si.RelationData[Artist]
+}
+
+func (l Label) GetModel() si.ModelOf[int64] {
+	return l.ModelOf
+}
+
+func (l Label) GetTable() string {
+	return "labels"
+}
+
+// Albums clears label_id when the label is hard deleted. A soft deleted label can be restored,
+// so NULLIFY leaves its albums untouched.
+func (l Label) Albums() *si.Relation[Label, Album] {
+	return si.HasMany[Label, Album](l, "LabelID", "albums", func(l *Label) *si.RelationData[Album] {
+		return &l.albums
+	}).OnDelete(si.NULLIFY)
+}
+
+// Artists restricts deleting a label that still has artists.
+func (l Label) Artists() *si.Relation[Label, Artist] {
+	return si.BelongsToMany[Label, Artist](l, "artist_labels", "LabelID", "ArtistID", "artists", func(l *Label) *si.RelationData[Artist] {
+		return &l.artists
+	}).OnDelete(si.RESTRICT)
+}
+
+// Genre uses its slug as a natural primary key.
+type Genre struct {
+	si.ModelOf[string]
+
+	Name string
+}
+
+// NewGenre returns a genre keyed by its slug.
+func NewGenre(slug, name string) Genre {
+	return Genre{ModelOf: si.ModelOf[string]{ID: &slug}, Name: name}
+}
+
+func (g Genre) GetModel() si.ModelOf[string] {
+	return g.ModelOf
+}
+
+func (g Genre) GetTable() string {
+	return "genres"
+}
+
+// Timestamps turns off all timestamp columns, genres is a plain lookup table.
+func (g Genre) Timestamps() si.Timestamps {
+	return si.Timestamps{}
+}
+
+// ArtistLabel has a composite primary key, the fields of the key struct are its columns.
+// It has no GetTable, so the naming strategy gives the table name artist_labels.
+type ArtistLabel struct {
+	si.ModelOf[ArtistLabelKey]
+
+	Role string
+}
+
+type ArtistLabelKey struct {
+	ArtistID uuid.UUID
+	LabelID  int64
+}
+
+func (a ArtistLabel) GetModel() si.ModelOf[ArtistLabelKey] {
+	return a.ModelOf
+}
+
+// Track has no GetTable, so the naming strategy gives the table name tracks.
+type Track struct {
+	si.Model
+
+	Name    string
+	Number  int
+	AlbumID uuid.UUID
+
+	album   si.RelationData[Album]
+	credits si.RelationData[TrackCredit]
+}
+
+func (t Track) GetModel() si.Model {
+	return t.Model
+}
+
+// Timestamps renames the columns, and tracks are always soft deleted, regardless of si.UseDeletedAt.
+func (t Track) Timestamps() si.Timestamps {
+	return si.Timestamps{
+		CreatedAt: "inserted_at",
+		UpdatedAt: "modified_at",
+		DeletedAt: "archived_at",
+	}
+}
+
+func (t Track) Album() *si.Relation[Track, Album] {
+	return si.BelongsTo[Track, Album](t, si.DeriveForeignKey, "album", func(t *Track) *si.RelationData[Album] {
+		return &t.album
+	})
+}
+
+func (t Track) Credits() *si.Relation[Track, TrackCredit] {
+	return si.HasMany[Track, TrackCredit](t, "TrackID", "credits", func(t *Track) *si.RelationData[TrackCredit] {
+		return &t.credits
+	}).OnDelete(si.HARD_DELETE)
+}
+
+type TrackCredit struct {
+	si.Model
+
+	Name    string
+	Role    string
+	TrackID uuid.UUID
+}
+
+func (t TrackCredit) GetModel() si.Model {
+	return t.Model
+}
+
+func (t TrackCredit) GetTable() string {
+	return "track_credits"
+}
+
+// Comment belongs to an artist, album or contact.
+// The "Owner" morph name refers to the OwnerType and OwnerID fields, where OwnerType holds the owner's table name.
+type Comment struct {
+	si.Model
+
+	Body      string
+	OwnerType string
+	OwnerID   uuid.UUID
+
+	owner si.RelationData[si.Modeler]
+}
+
+func (c Comment) GetModel() si.Model {
+	return c.Model
+}
+
+func (c Comment) GetTable() string {
+	return "comments"
+}
+
+// Owner is one of Artist, Album or Contact, depending on OwnerType.
+func (c Comment) Owner() *si.MorphRelation[Comment] {
+	return si.MorphTo[Comment](c, "Owner", "owner", func(c *Comment) *si.RelationData[si.Modeler] {
+		return &c.owner
+	}, Artist{}, Album{}, Contact{})
+}
@@ -0,0 +1,83 @@
+//go:build integration
+
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/derivatan/si"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultNaming(t *testing.T) {
+	naming := si.SnakeCaseNaming{}
+
+	assert.Equal(t, "artists", naming.TableName("Artist"))
+	assert.Equal(t, "artist_labels", naming.TableName("ArtistLabel"))
+	assert.Equal(t, "categories", naming.TableName("Category"))
+	assert.Equal(t, "radio_frequency", naming.ColumnName("RadioFrequency"))
+	assert.Equal(t, "artist_id", naming.ColumnName("ArtistID"))
+	assert.Equal(t, "homepage_url", naming.ColumnName("HomepageURL"))
+	assert.Equal(t, "album_id", naming.ForeignKey("Album"))
+}
+
+func TestNamingAcronyms(t *testing.T) {
+	// The listed acronyms are kept together, in addition to the default ones.
+	naming := si.SnakeCaseNaming{Acronyms: []string{"DJ"}}
+
+	assert.Equal(t, "dj_name", naming.ColumnName("DJName"))
+	assert.Equal(t, "artist_id", naming.ColumnName("ArtistID"))
+	assert.Equal(t, "homepage_url", naming.ColumnName("HomepageURL"))
+}
+
+func TestNamingReplaceAcronyms(t *testing.T) {
+	// Without the default acronyms, only the listed ones are kept together.
+	naming := si.SnakeCaseNaming{Acronyms: []string{"ID"}, NoDefaultAcronyms: true}
+
+	assert.Equal(t, "artist_id", naming.ColumnName("ArtistID"))
+	assert.Equal(t, "homepage_u_r_l", naming.ColumnName("HomepageURL"))
+}
+
+type prefixNaming struct {
+	si.SnakeCaseNaming
+}
+
+func (p prefixNaming) TableName(model string) string {
+	return "tbl_" + strings.ToLower(model)
+}
+
+func TestSetNamingStrategy(t *testing.T) {
+	si.SetNamingStrategy(prefixNaming{})
+	defer si.SetNamingStrategy(si.SnakeCaseNaming{})
+
+	// GetTable always wins over the naming strategy.
+	assert.Equal(t, "artists", si.TableName[Artist]())
+	assert.Equal(t, "tbl_track", si.TableName[Track]())
+}
+
+func TestNamingStrategyRelation(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Broder Daniel"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Cruel Town", ArtistID: ids[0]},
+	})
+	Seed(db, []Track{
+		{Name: "Shoreline", Number: 1, AlbumID: albumIDs[0]},
+		{Name: "Underground", Number: 2, AlbumID: albumIDs[0]},
+	})
+
+	// Both the table and the foreign key album_id come from the naming strategy.
+	album := si.Query[Album]().MustFind(db, albumIDs[0])
+	tracks, err := album.Tracks().Get(db)
+	track, err2 := si.Query[Track]().Where("number", "=", 1).Find(db)
+	trackAlbum, err3 := track.Album().Find(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, tracks, 2)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, "Cruel Town", trackAlbum.Name)
+}
@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"database/sql"
 	"testing"
 	"time"
 
@@ -600,3 +601,1187 @@ func TestJoinWithDeleted(t *testing.T) {
 	assert.Len(t, list, 1)
 	assert.Equal(t, name, list[0].Name)
 }
+
+func TestJoinWithBelongsTo(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Massive Attack"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Mezzanine", ArtistID: ids[0]},
+	})
+
+	album, err := si.Query[Album]().JoinWith(func(t Album) *si.JoinConf {
+		return t.Artist().Join(si.INNER)
+	}).First(db)
+	// db is not needed here since the artist was selected in the same query.
+	artist, artistErr := album.Artist().First(nil)
+
+	assert.NoError(t, err)
+	assert.NoError(t, artistErr)
+	assert.True(t, album.Artist().Loaded())
+	assert.Equal(t, "Mezzanine", album.Name)
+	assert.Equal(t, "Massive Attack", artist.Name)
+	// Both tables have id, created_at etc. and must not be mixed up.
+	assert.Equal(t, albumIDs[0], *album.ID)
+	assert.Equal(t, ids[0], *artist.ID)
+}
+
+func TestJoinWithHasOne(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Air"},
+		{Name: "Daft Punk"},
+	})
+	Seed(db, []Contact{
+		{Email: "air@moon.fr", Phone: 1998, ArtistID: ids[0]},
+	})
+
+	artists, err := si.Query[Artist]().JoinWith(func(t Artist) *si.JoinConf {
+		return t.Contact().Join(si.LEFT)
+	}).OrderBy("artists.name", true).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, artists, 2)
+	assert.True(t, artists[0].Contact().Loaded())
+	assert.True(t, artists[1].Contact().Loaded())
+	assert.Equal(t, "air@moon.fr", artists[0].Contact().MustFirst(nil).Email)
+	assert.Equal(t, ids[0], artists[0].Contact().MustFirst(nil).ArtistID)
+	// A LEFT join without a match is loaded, but empty.
+	assert.Nil(t, artists[1].Contact().MustFirst(nil))
+}
+
+func TestWhereSearch(t *testing.T) {
+	db := DB(t)
+	Seed(db, []Artist{
+		{Name: "Echo & the Bunnymen"},
+		{Name: "Bunny Wailer", Nickname: "Jah B"},
+		{Name: "Echoes of Silence"},
+	})
+
+	// plainto_tsquery: every word must match, in any of the columns.
+	list, err := si.Query[Artist]().WhereSearch([]string{"name", "nickname"}, "wailer jah", si.SearchConfig{
+		Language: "simple",
+	}).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "Bunny Wailer", list[0].Name)
+}
+
+func TestWhereSearchWeb(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Pink Floyd"},
+	})
+	Seed(db, []Album{
+		{Name: "The Wall", ArtistID: ids[0]},
+		{Name: "Wish You Were Here", ArtistID: ids[0]},
+		{Name: "The Final Cut", ArtistID: ids[0]},
+	})
+
+	// websearch_to_tsquery: quoted phrases, "or" and "-" exclusions.
+	list, err := si.Query[Album]().WhereSearch([]string{"name"}, `"the wall" or cut -final`, si.SearchConfig{
+		Language: "english",
+		Web:      true,
+	}).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "The Wall", list[0].Name)
+}
+
+func TestOrderByRank(t *testing.T) {
+	db := DB(t)
+	Seed(db, []Artist{
+		{Name: "Love", Nickname: "Arthur Lee"},
+		{Name: "Love Love Love", Nickname: "Love"},
+		{Name: "Courtney Love"},
+		{Name: "Hole"},
+	})
+
+	list, err := si.Query[Artist]().WhereSearch([]string{"name", "nickname"}, "love", si.SearchConfig{
+		Language: "simple",
+	}).OrderByRank().Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+	// The best match comes first.
+	assert.Equal(t, "Love Love Love", list[0].Name)
+}
+
+func TestWhereJSON(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Joy Division"},
+	})
+	Seed(db, []Album{
+		{Name: "Unknown Pleasures", Details: AlbumDetails{Label: "Factory", Tracks: 10}, ArtistID: ids[0]},
+		{Name: "Closer", Details: AlbumDetails{Label: "Factory", Tracks: 9}, ArtistID: ids[0]},
+		{Name: "Still", Details: AlbumDetails{Label: "Factory", Tracks: 18}, ArtistID: ids[0]},
+	})
+
+	// The column may be qualified with its table, and the path is a dotted path inside the document.
+	list, err := si.Query[Album]().
+		WhereJSON("details", "label", "=", "Factory").
+		// A number value compares the path as a number. Compared as text "9" < "12" is false, and Closer would be missing.
+		WhereJSON("albums.details", "tracks", "<", 12).
+		OrderBy("name", true).
+		Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "Closer", list[0].Name)
+	assert.Equal(t, "Unknown Pleasures", list[1].Name)
+}
+
+func TestWhereJSONContains(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "The Prodigy"},
+	})
+	Seed(db, []Album{
+		{Name: "Music for the Jilted Generation", Credits: map[string][]string{"producer": {"Liam Howlett"}}, ArtistID: ids[0]},
+		{Name: "Invaders Must Die", Credits: map[string][]string{"producer": {"Liam Howlett", "James Rushent"}}, ArtistID: ids[0]},
+		{Name: "Experience", Musicians: []string{"Liam Howlett", "Keith Flint", "Leeroy Thornhill"}, ArtistID: ids[0]},
+	})
+
+	// credits @> '{"producer": ["James Rushent"]}'
+	list, err := si.Query[Album]().WhereJSONContains("credits", map[string][]string{
+		"producer": {"James Rushent"},
+	}).Get(db)
+
+	// musicians @> '["Keith Flint"]'
+	withKeith, err2 := si.Query[Album]().WhereJSONContains("musicians", []string{"Keith Flint"}).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "Invaders Must Die", list[0].Name)
+	assert.NoError(t, err2)
+	assert.Len(t, withKeith, 1)
+	assert.Equal(t, "Experience", withKeith[0].Name)
+}
+
+func TestWhereArrayContains(t *testing.T) {
+	db := DB(t)
+	Seed(db, []Artist{
+		{Name: "Sepultura", Genres: []string{"thrash metal", "groove metal"}},
+		{Name: "Pantera", Genres: []string{"groove metal"}},
+		{Name: "Slayer", Genres: []string{"thrash metal"}},
+	})
+
+	// genres @> '{thrash metal, groove metal}'
+	list, err := si.Query[Artist]().WhereContains("genres", []string{"thrash metal", "groove metal"}).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "Sepultura", list[0].Name)
+}
+
+func TestWhereArrayOverlaps(t *testing.T) {
+	db := DB(t)
+	Seed(db, []Artist{
+		{Name: "Abba", Genres: []string{"pop", "disco"}},
+		{Name: "Chic", Genres: []string{"disco", "funk"}},
+		{Name: "Parliament", Genres: []string{"funk"}},
+		{Name: "Roxette"},
+	})
+
+	// genres && '{pop, funk}'
+	list, err := si.Query[Artist]().WhereOverlaps("genres", []string{"pop", "funk"}).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+}
+
+func TestWhereAny(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Gorillaz"},
+		{Name: "De La Soul"},
+		{Name: "Snoop Dogg"},
+	})
+	Seed(db, []Album{
+		{Name: "Demon Days", Charts: []int64{1, 6}, Guests: []uuid.UUID{ids[1]}, ArtistID: ids[0]},
+		{Name: "Plastic Beach", Charts: []int64{2, 2}, Guests: []uuid.UUID{ids[1], ids[2]}, ArtistID: ids[0]},
+		{Name: "Humanz", Charts: []int64{2}, ArtistID: ids[0]},
+	})
+
+	// $1 = ANY(guests)
+	withGuest, err := si.Query[Album]().WhereAny("guests", ids[2]).Get(db)
+	// $1 = ANY(charts)
+	numberOne, err2 := si.Query[Album]().WhereAny("charts", 1).Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Len(t, withGuest, 1)
+	assert.Equal(t, "Plastic Beach", withGuest[0].Name)
+	assert.Len(t, numberOne, 1)
+	assert.Equal(t, "Demon Days", numberOne[0].Name)
+}
+
+func TestWhereCustomTypes(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Mike Oldfield"},
+	})
+	catalogID := CatalogID{uuid.New()}
+	Seed(db, []Album{
+		{Name: "Tubular Bells", Price: 1299, Length: 49 * time.Minute, CatalogID: &catalogID, ArtistID: ids[0]},
+		{Name: "Hergest Ridge", Price: 999, Length: 40 * time.Minute, ArtistID: ids[0]},
+		{Name: "Ommadawn", Price: 999, Length: 36 * time.Minute, ArtistID: ids[0]},
+	})
+	Seed(db, []Contact{
+		{Email: "mike@oldfield.com", Preferred: ContactMethodPhone, ArtistID: ids[0]},
+	})
+
+	cheap, err := si.Query[Album]().Where("price", "<", Money(1000)).Where("length", ">", 38*time.Minute).Get(db)
+	byCatalog, err2 := si.Query[Album]().Where("catalog_id", "=", catalogID).Get(db)
+	contacts, err3 := si.Query[Contact]().Where("preferred", "=", ContactMethodPhone).Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Len(t, cheap, 1)
+	assert.Equal(t, "Hergest Ridge", cheap[0].Name)
+	assert.Len(t, byCatalog, 1)
+	assert.Equal(t, "Tubular Bells", byCatalog[0].Name)
+	assert.Len(t, contacts, 1)
+}
+
+func TestRelationSerialPrimaryKey(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Autechre"},
+	})
+	labelIDs := SeedKeys[int64](db, []Label{
+		{Name: "Warp"},
+	})
+	Seed(db, []Album{
+		{Name: "Amber", LabelID: &labelIDs[0], ArtistID: ids[0]},
+		{Name: "Tri Repetae", LabelID: &labelIDs[0], ArtistID: ids[0]},
+		{Name: "Self-released", ArtistID: ids[0]},
+	})
+	label := si.Query[Label]().MustFind(db, labelIDs[0])
+
+	album, err := si.Query[Album]().Where("name", "=", "Amber").Find(db)
+	albumLabel, err2 := album.Label().Find(db)
+	albums, err3 := label.Albums().Get(db)
+	labels, err4 := si.Query[Label]().With(func(m Label, r []Label) error {
+		return m.Albums().Execute(db, r)
+	}).Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, labelIDs[0], *albumLabel.ID)
+	assert.NoError(t, err3)
+	assert.Len(t, albums, 2)
+	assert.NoError(t, err4)
+	assert.Len(t, labels, 1)
+	assert.Len(t, labels[0].Albums().MustGet(nil), 2)
+}
+
+func TestRelationBelongsToMany(t *testing.T) {
+	db := DB(t)
+	SeedKeys[string](db, []Genre{
+		NewGenre("trip-hop", "Trip hop"),
+		NewGenre("dub", "Dub"),
+		NewGenre("jazz", "Jazz"),
+	})
+	ids := Seed(db, []Artist{
+		{Name: "Portishead"},
+	})
+	Seed(db, []Album{
+		{Name: "Dummy", ArtistID: ids[0]},
+	})
+	album := si.Query[Album]().MustFind(db)
+	assert.NoError(t, album.Genres().Attach(db, "trip-hop"))
+	assert.NoError(t, album.Genres().Attach(db, "dub"))
+
+	genres, err := album.Genres().Get(db)
+	found, err2 := album.Genres().Find(db, "dub")
+	_, missingErr := album.Genres().Find(db, "jazz")
+	first, err3 := album.Genres().First(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, genres, 2)
+	assert.NoError(t, err2)
+	assert.Equal(t, "Dub", found.Name)
+	assert.ErrorIs(t, missingErr, si.ResourceNotFoundError{})
+	assert.NoError(t, err3)
+	assert.Contains(t, []string{"trip-hop", "dub"}, *first.ID)
+}
+
+func TestRelationWithBelongsToMany(t *testing.T) {
+	db := DB(t)
+	SeedKeys[string](db, []Genre{
+		NewGenre("krautrock", "Krautrock"),
+		NewGenre("electronic", "Electronic"),
+	})
+	ids := Seed(db, []Artist{
+		{Name: "Kraftwerk"},
+	})
+	Seed(db, []Album{
+		{Name: "Autobahn", Year: 1974, ArtistID: ids[0]},
+		{Name: "Computerwelt", Year: 1981, ArtistID: ids[0]},
+		{Name: "Tour de France", Year: 2003, ArtistID: ids[0]},
+	})
+	albums := si.Query[Album]().OrderBy("year", true).MustGet(db)
+	assert.NoError(t, albums[0].Genres().Sync(db, "krautrock", "electronic"))
+	assert.NoError(t, albums[1].Genres().Sync(db, "electronic"))
+
+	list, err := si.Query[Album]().With(func(m Album, r []Album) error {
+		return m.Genres().Execute(db, r)
+	}).OrderBy("year", true).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+	assert.Len(t, list[0].Genres().MustGet(nil), 2)
+	assert.Len(t, list[1].Genres().MustGet(nil), 1)
+	assert.True(t, list[2].Genres().Loaded())
+	assert.Len(t, list[2].Genres().MustGet(nil), 0)
+}
+
+func TestRelationBelongsToManyPivot(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Jack White"},
+	})
+	labelIDs := SeedKeys[int64](db, []Label{
+		{Name: "Third Man Records"},
+	})
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+	assert.NoError(t, artist.Labels().Attach(db, labelIDs[0], si.Pivot{"role": "founder"}))
+
+	labels, err := artist.Labels().Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, labels, 1)
+	assert.Equal(t, "Third Man Records", labels[0].Name)
+	assert.Equal(t, "founder", labels[0].Pivot["role"])
+}
+
+func TestJoinBelongsToMany(t *testing.T) {
+	db := DB(t)
+	SeedKeys[string](db, []Genre{
+		NewGenre("grunge", "Grunge"),
+		NewGenre("pop", "Pop"),
+	})
+	ids := Seed(db, []Artist{
+		{Name: "Soundgarden"},
+	})
+	Seed(db, []Album{
+		{Name: "Superunknown", ArtistID: ids[0]},
+		{Name: "Badmotorfinger", ArtistID: ids[0]},
+	})
+	album := si.Query[Album]().Where("name", "=", "Superunknown").MustFind(db)
+	assert.NoError(t, album.Genres().Attach(db, "grunge"))
+
+	list, err := si.Query[Album]().Join(func(t Album) *si.JoinConf {
+		return t.Genres().Join(si.INNER)
+	}).Where("genres.name", "=", "Grunge").Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "Superunknown", list[0].Name)
+}
+
+func TestRelationHasManyThrough(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	now := time.Now()
+	ids := Seed(db, []Artist{
+		{Name: "Queens of the Stone Age"},
+		{Name: "Kyuss"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Songs for the Deaf", ArtistID: ids[0]},
+		{Name: "Era Vulgaris", ArtistID: ids[0]},
+		{Name: "Rated R", ArtistID: ids[0], Model: si.Model{DeletedAt: &now}},
+		{Name: "Welcome to Sky Valley", ArtistID: ids[1]},
+	})
+	Seed(db, []Track{
+		{Name: "No One Knows", Number: 2, AlbumID: albumIDs[0]},
+		{Name: "Go with the Flow", Number: 8, AlbumID: albumIDs[0]},
+		{Name: "Sick, Sick, Sick", Number: 2, AlbumID: albumIDs[1]},
+		{Name: "Make It wit Chu", Number: 11, AlbumID: albumIDs[1], Model: si.Model{DeletedAt: &now}},
+		{Name: "The Lost Art of Keeping a Secret", Number: 2, AlbumID: albumIDs[2]},
+		{Name: "Gardenia", Number: 1, AlbumID: albumIDs[3]},
+	})
+
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+	// Tracks of deleted albums and deleted tracks are left out.
+	tracks, err := artist.Tracks().Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, tracks, 3)
+}
+
+func TestRelationWithHasManyThrough(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Beck"},
+		{Name: "Cibo Matto"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Odelay", ArtistID: ids[0]},
+		{Name: "Sea Change", ArtistID: ids[0]},
+		{Name: "Viva! La Woman", ArtistID: ids[1]},
+	})
+	Seed(db, []Track{
+		{Name: "Devils Haircut", Number: 1, AlbumID: albumIDs[0]},
+		{Name: "Where It's At", Number: 3, AlbumID: albumIDs[0]},
+		{Name: "Lost Cause", Number: 3, AlbumID: albumIDs[1]},
+		{Name: "Know Your Chicken", Number: 2, AlbumID: albumIDs[2]},
+	})
+
+	artists, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Tracks().Execute(db, r)
+	}).OrderBy("name", true).Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, artists, 2)
+	assert.Len(t, artists[0].Tracks().MustGet(nil), 3)
+	assert.Len(t, artists[1].Tracks().MustGet(nil), 1)
+}
+
+func TestJoinHasManyThrough(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Neil Young"},
+		{Name: "Crosby, Stills & Nash"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Harvest", ArtistID: ids[0]},
+		{Name: "Crosby, Stills & Nash", ArtistID: ids[1]},
+	})
+	Seed(db, []Track{
+		{Name: "Heart of Gold", Number: 4, AlbumID: albumIDs[0]},
+		{Name: "Old Man", Number: 2, AlbumID: albumIDs[0]},
+		{Name: "Marrakesh Express", Number: 2, AlbumID: albumIDs[1]},
+	})
+
+	artists, err := si.Query[Artist]().Join(func(t Artist) *si.JoinConf {
+		return t.Tracks().Join(si.INNER)
+	}).Where("tracks.name", "ILIKE", "%gold%").Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, artists, 1)
+	assert.Equal(t, "Neil Young", artists[0].Name)
+}
+
+func TestRelationHasOneThrough(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Tom Waits"},
+		{Name: "Nick Cave"},
+	})
+	Seed(db, []Contact{
+		{Email: "tom@waits.com", ArtistID: ids[0]},
+		{Email: "nick@badseeds.com", ArtistID: ids[1]},
+	})
+	Seed(db, []Album{
+		{Name: "Rain Dogs", Year: 1985, ArtistID: ids[0]},
+		{Name: "Murder Ballads", Year: 1996, ArtistID: ids[1]},
+	})
+
+	album := si.Query[Album]().Where("name", "=", "Rain Dogs").MustFind(db)
+	contact, err := album.Contact().First(db)
+	albums, err2 := si.Query[Album]().With(func(m Album, r []Album) error {
+		return m.Contact().Execute(db, r)
+	}).OrderBy("year", true).Get(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "tom@waits.com", contact.Email)
+	assert.NoError(t, err2)
+	assert.Equal(t, "tom@waits.com", albums[0].Contact().MustFirst(nil).Email)
+	assert.Equal(t, "nick@badseeds.com", albums[1].Contact().MustFirst(nil).Email)
+}
+
+func TestRelationMorphMany(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Arcade Fire"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Funeral", ArtistID: ids[0]},
+	})
+	Seed(db, []Comment{
+		{Body: "Great live band", OwnerType: "artists", OwnerID: ids[0]},
+		{Body: "From Montreal", OwnerType: "artists", OwnerID: ids[0]},
+		{Body: "Debut album", OwnerType: "albums", OwnerID: albumIDs[0]},
+	})
+
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+	album := si.Query[Album]().MustFind(db, albumIDs[0])
+	artistComments, err := artist.Comments().Get(db)
+	albumComments, err2 := album.Comments().Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, artistComments, 2)
+	assert.NoError(t, err2)
+	assert.Len(t, albumComments, 1)
+	assert.Equal(t, "Debut album", albumComments[0].Body)
+}
+
+func TestRelationMorphTo(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "LCD Soundsystem"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Sound of Silver", ArtistID: ids[0]},
+	})
+	Seed(db, []Comment{
+		{Body: "All My Friends", OwnerType: "albums", OwnerID: albumIDs[0]},
+	})
+
+	comment := si.Query[Comment]().MustFind(db)
+	owner, err := comment.Owner().First(db)
+	album, ok := owner.(Album)
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Sound of Silver", album.Name)
+}
+
+func TestRelationWithMorphTo(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "The National"},
+		{Name: "Bon Iver"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Boxer", Year: 2007, ArtistID: ids[0]},
+		{Name: "High Violet", Year: 2010, ArtistID: ids[0]},
+	})
+	contactIDs := Seed(db, []Contact{
+		{Email: "info@americanmary.com", ArtistID: ids[0]},
+	})
+	Seed(db, []Comment{
+		{Body: "1", OwnerType: "artists", OwnerID: ids[0]},
+		{Body: "2", OwnerType: "artists", OwnerID: ids[1]},
+		{Body: "3", OwnerType: "albums", OwnerID: albumIDs[0]},
+		{Body: "4", OwnerType: "albums", OwnerID: albumIDs[1]},
+		{Body: "5", OwnerType: "contacts", OwnerID: contactIDs[0]},
+	})
+	queries := CountQueries(t)
+
+	comments, err := si.Query[Comment]().With(func(m Comment, r []Comment) error {
+		return m.Owner().Execute(db, r)
+	}).OrderBy("body", true).Get(db)
+
+	assert.NoError(t, err)
+	// One query for the comments, and one for each owner type.
+	assert.Equal(t, 4, *queries)
+	assert.Len(t, comments, 5)
+	assert.Equal(t, "The National", comments[0].Owner().MustFirst(nil).(Artist).Name)
+	assert.Equal(t, "Bon Iver", comments[1].Owner().MustFirst(nil).(Artist).Name)
+	assert.Equal(t, "Boxer", comments[2].Owner().MustFirst(nil).(Album).Name)
+	assert.Equal(t, "High Violet", comments[3].Owner().MustFirst(nil).(Album).Name)
+	assert.Equal(t, "info@americanmary.com", comments[4].Owner().MustFirst(nil).(Contact).Email)
+}
+
+// seedDiscography seeds two artists, with albums, tracks and credits.
+func seedDiscography(db si.DB) {
+	ids := Seed(db, []Artist{
+		{Name: "Fever Ray"},
+		{Name: "The Knife"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Fever Ray", ArtistID: ids[0]},
+		{Name: "Silent Shout", ArtistID: ids[1]},
+		{Name: "Shaking the Habitual", ArtistID: ids[1]},
+	})
+	trackIDs := Seed(db, []Track{
+		{Name: "If I Had a Heart", Number: 1, AlbumID: albumIDs[0]},
+		{Name: "When I Grow Up", Number: 2, AlbumID: albumIDs[0]},
+		{Name: "Silent Shout", Number: 1, AlbumID: albumIDs[1]},
+		{Name: "A Tooth for an Eye", Number: 1, AlbumID: albumIDs[2]},
+	})
+	Seed(db, []TrackCredit{
+		{Name: "Karin Dreijer", Role: "vocals", TrackID: trackIDs[0]},
+		{Name: "Christoffer Berg", Role: "producer", TrackID: trackIDs[0]},
+		{Name: "Karin Dreijer", Role: "vocals", TrackID: trackIDs[2]},
+		{Name: "Olof Dreijer", Role: "producer", TrackID: trackIDs[2]},
+	})
+}
+
+func TestRelationWithNested(t *testing.T) {
+	db := DB(t)
+	seedDiscography(db)
+	queries := CountQueries(t)
+
+	artists, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().With(func(m Album, r []Album) error {
+			return m.Tracks().With(func(m Track, r []Track) error {
+				return m.Credits().Execute(db, r)
+			}).Execute(db, r)
+		}).Execute(db, r)
+	}).OrderBy("name", true).Get(db)
+
+	assert.NoError(t, err)
+	// One query per level.
+	assert.Equal(t, 4, *queries)
+	assert.Len(t, artists, 2)
+	feverRay := artists[0].Albums().MustGet(nil)
+	assert.Len(t, feverRay, 1)
+	tracks := feverRay[0].Tracks().MustGet(nil)
+	assert.Len(t, tracks, 2)
+	for _, track := range tracks {
+		assert.True(t, track.Credits().Loaded())
+	}
+	assert.Len(t, artists[1].Albums().MustGet(nil), 2)
+}
+
+func TestRelationWithPath(t *testing.T) {
+	db := DB(t)
+	seedDiscography(db)
+	queries := CountQueries(t)
+
+	artists, err := si.Query[Artist]().WithPath("Albums.Tracks.Credits").OrderBy("name", true).Get(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, *queries)
+	assert.Len(t, artists, 2)
+	credits := 0
+	for _, album := range artists[1].Albums().MustGet(nil) {
+		for _, track := range album.Tracks().MustGet(nil) {
+			credits += len(track.Credits().MustGet(nil))
+		}
+	}
+	assert.Equal(t, 2, credits)
+}
+
+func TestRelationWithPathUnknown(t *testing.T) {
+	db := DB(t)
+
+	_, err := si.Query[Artist]().WithPath("Albums.Lyrics").Get(db)
+
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "Lyrics")
+}
+
+func TestRelationConstrain(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Depeche Mode"},
+	})
+	Seed(db, []Album{
+		{Name: "Violator", Year: 1990, ArtistID: ids[0]},
+		{Name: "Exciter", Year: 2001, ArtistID: ids[0]},
+		{Name: "Playing the Angel", Year: 2005, ArtistID: ids[0]},
+	})
+	newestFirst := func(q *si.Q[Album]) *si.Q[Album] {
+		return q.Where("year", ">", 2000).OrderBy("year", false)
+	}
+
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+	albums, err := artist.Albums().Constrain(newestFirst).Get(db)
+	album, err2 := artist.Albums().Constrain(newestFirst).First(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, albums, 2)
+	assert.Equal(t, "Playing the Angel", albums[0].Name)
+	assert.Equal(t, "Exciter", albums[1].Name)
+	assert.NoError(t, err2)
+	assert.Equal(t, "Playing the Angel", album.Name)
+}
+
+func TestRelationWithConstrain(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Pet Shop Boys"},
+		{Name: "Erasure"},
+	})
+	Seed(db, []Album{
+		{Name: "Actually", Year: 1987, ArtistID: ids[0]},
+		{Name: "Yes", Year: 2009, ArtistID: ids[0]},
+		{Name: "Electric", Year: 2013, ArtistID: ids[0]},
+		{Name: "The Innocents", Year: 1988, ArtistID: ids[1]},
+	})
+
+	artists, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Constrain(func(q *si.Q[Album]) *si.Q[Album] {
+			return q.Where("year", ">", 2000).OrderBy("year", false)
+		}).Execute(db, r)
+	}).OrderBy("name", true).Get(db)
+	unconstrained, err2 := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Execute(db, r)
+	}).First(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, artists, 2)
+	assert.True(t, artists[0].Albums().Loaded())
+	assert.True(t, artists[0].Albums().Constrained())
+	assert.Len(t, artists[0].Albums().MustGet(nil), 0)
+	albums := artists[1].Albums().MustGet(nil)
+	assert.Len(t, albums, 2)
+	assert.Equal(t, "Electric", albums[0].Name)
+	assert.Equal(t, "Yes", albums[1].Name)
+	assert.NoError(t, err2)
+	assert.False(t, unconstrained.Albums().Constrained())
+}
+
+func TestRelationWithTakeEach(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "David Bowie"},
+		{Name: "Iggy Pop"},
+		{Name: "Lou Reed"},
+	})
+	Seed(db, []Album{
+		{Name: "Hunky Dory", Year: 1971, ArtistID: ids[0]},
+		{Name: "Low", Year: 1977, ArtistID: ids[0]},
+		{Name: "Heroes", Year: 1977, ArtistID: ids[0]},
+		{Name: "Scary Monsters", Year: 1980, ArtistID: ids[0]},
+		{Name: "Blackstar", Year: 2016, ArtistID: ids[0]},
+		{Name: "The Idiot", Year: 1977, ArtistID: ids[1]},
+		{Name: "Lust for Life", Year: 1977, ArtistID: ids[1]},
+	})
+	queries := CountQueries(t)
+
+	artists, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Constrain(func(q *si.Q[Album]) *si.Q[Album] {
+			return q.OrderBy("year", false).OrderBy("name", true)
+		}).TakeEach(3).Execute(db, r)
+	}).OrderBy("name", true).Get(db)
+
+	assert.NoError(t, err)
+	// One query for the artists, and a single one for all the albums.
+	assert.Equal(t, 2, *queries)
+	assert.Len(t, artists, 3)
+	bowie := artists[0].Albums().MustGet(nil)
+	assert.Len(t, bowie, 3)
+	assert.Equal(t, "Blackstar", bowie[0].Name)
+	assert.Equal(t, "Scary Monsters", bowie[1].Name)
+	assert.Equal(t, "Heroes", bowie[2].Name)
+	assert.Len(t, artists[1].Albums().MustGet(nil), 2)
+	assert.True(t, artists[2].Albums().Loaded())
+	assert.Len(t, artists[2].Albums().MustGet(nil), 0)
+	// Only a subset of the albums are loaded.
+	assert.True(t, artists[0].Albums().Constrained())
+}
+
+func TestWithCount(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	now := time.Now()
+	ids := Seed(db, []Artist{
+		{Name: "Bruce Springsteen"},
+		{Name: "Patti Smith"},
+		{Name: "Tom Petty"},
+	})
+	Seed(db, []Album{
+		{Name: "Born to Run", ArtistID: ids[0]},
+		{Name: "Nebraska", ArtistID: ids[0]},
+		{Name: "The River", ArtistID: ids[0], Model: si.Model{DeletedAt: &now}},
+		{Name: "Horses", ArtistID: ids[1]},
+	})
+	queries := CountQueries(t)
+
+	artists, err := si.Query[Artist]().WithCount("albums").OrderBy("name", true).Get(db)
+	plain, err2 := si.Query[Artist]().OrderBy("name", true).First(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *queries)
+	assert.Len(t, artists, 3)
+	assert.Equal(t, 2, artists[0].AlbumsCount)
+	assert.Equal(t, 1, artists[1].AlbumsCount)
+	assert.Equal(t, 0, artists[2].AlbumsCount)
+	// The albums themselves are not loaded.
+	assert.False(t, artists[0].Albums().Loaded())
+	assert.NoError(t, err2)
+	assert.Equal(t, 0, plain.AlbumsCount)
+}
+
+func TestWithAggregates(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Leonard Cohen"},
+		{Name: "Joni Mitchell"},
+		{Name: "Townes Van Zandt"},
+	})
+	Seed(db, []Album{
+		{Name: "Songs of Leonard Cohen", Year: 1967, Price: 999, ArtistID: ids[0]},
+		{Name: "I'm Your Man", Year: 1988, Price: 1299, ArtistID: ids[0]},
+		{Name: "You Want It Darker", Year: 2016, Price: 1499, ArtistID: ids[0]},
+		{Name: "Blue", Year: 1971, Price: 899, ArtistID: ids[1]},
+	})
+
+	artists, err := si.Query[Artist]().
+		WithSum("albums", "price").
+		WithMin("albums", "year").
+		WithMax("albums", "year").
+		WithAvg("albums", "year").
+		OrderBy("name", true).
+		Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, artists, 3)
+	// The sum is NUMERIC in postgres, so this also covers scanning Money from text.
+	assert.Equal(t, Money(899), artists[0].AlbumsSumPrice)
+	assert.Equal(t, sql.Null[int]{V: 1971, Valid: true}, artists[0].AlbumsMaxYear)
+	cohen := artists[1]
+	assert.Equal(t, Money(3797), cohen.AlbumsSumPrice)
+	assert.Equal(t, sql.Null[int]{V: 1967, Valid: true}, cohen.AlbumsMinYear)
+	assert.Equal(t, sql.Null[int]{V: 2016, Valid: true}, cohen.AlbumsMaxYear)
+	assert.True(t, cohen.AlbumsAvgYear.Valid)
+	assert.InDelta(t, 1990.33, cohen.AlbumsAvgYear.V, 0.01)
+	// Townes Van Zandt has no albums.
+	townes := artists[2]
+	assert.Equal(t, Money(0), townes.AlbumsSumPrice)
+	assert.False(t, townes.AlbumsMinYear.Valid)
+	assert.False(t, townes.AlbumsMaxYear.Valid)
+	assert.False(t, townes.AlbumsAvgYear.Valid)
+}
+
+func TestRelationCountAndExists(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Massive Attack"},
+		{Name: "Tricky"},
+	})
+	Seed(db, []Album{
+		{Name: "Blue Lines", ArtistID: ids[0]},
+		{Name: "Protection", ArtistID: ids[0]},
+		{Name: "Mezzanine", ArtistID: ids[0]},
+	})
+	massiveAttack := si.Query[Artist]().MustFind(db, ids[0])
+	tricky := si.Query[Artist]().MustFind(db, ids[1])
+	queries := CountQueries(t)
+
+	count, err := massiveAttack.Albums().Count(db)
+	exists, err2 := massiveAttack.Albums().Exists(db)
+	trickyCount, err3 := tricky.Albums().Count(db)
+	trickyExists, err4 := tricky.Albums().Exists(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, err2)
+	assert.True(t, exists)
+	assert.NoError(t, err3)
+	assert.Equal(t, 0, trickyCount)
+	assert.NoError(t, err4)
+	assert.False(t, trickyExists)
+	// One COUNT or EXISTS query each, the albums are never fetched.
+	assert.Equal(t, 4, *queries)
+	assert.False(t, massiveAttack.Albums().Loaded())
+}
+
+func TestRelationCountLoaded(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Sigur Rós"},
+	})
+	Seed(db, []Album{
+		{Name: "Ágætis byrjun", ArtistID: ids[0]},
+		{Name: "Takk...", ArtistID: ids[0]},
+	})
+	artist, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Execute(db, r)
+	}).First(db)
+	queries := CountQueries(t)
+
+	// db is not needed here since the albums are already loaded.
+	count, err2 := artist.Albums().Count(nil)
+	exists, err3 := artist.Albums().Exists(nil)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, 2, count)
+	assert.NoError(t, err3)
+	assert.True(t, exists)
+	assert.Equal(t, 0, *queries)
+}
+
+func TestRelationPluck(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "The Velvet Underground"},
+	})
+	Seed(db, []Album{
+		{Name: "Loaded", Year: 1970, ArtistID: ids[0]},
+		{Name: "White Light/White Heat", Year: 1968, ArtistID: ids[0]},
+	})
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+	loaded, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Execute(db, r)
+	}).First(db)
+
+	var names []string
+	err2 := artist.Albums().Pluck(db, "name", &names)
+	var years []int
+	err3 := loaded.Albums().Pluck(nil, "year", &years)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.ElementsMatch(t, []string{"Loaded", "White Light/White Heat"}, names)
+	assert.NoError(t, err3)
+	assert.ElementsMatch(t, []int{1970, 1968}, years)
+}
+
+func TestRelationWhereLoaded(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "R.E.M."},
+	})
+	Seed(db, []Album{
+		{Name: "Murmur", Year: 1983, ArtistID: ids[0]},
+		{Name: "Document", Year: 1987, ArtistID: ids[0]},
+		{Name: "Out of Time", Year: 1991, ArtistID: ids[0]},
+		{Name: "Automatic for the People", Year: 1992, ArtistID: ids[0]},
+		{Name: "Monster", Year: 1994, ArtistID: ids[0]},
+	})
+	artist, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Execute(db, r)
+	}).First(db)
+	queries := CountQueries(t)
+
+	// db is not needed here since the albums are already loaded.
+	nineties, err2 := artist.Albums().Where("year", ">=", 1990).SortBy("year", false).Get(nil)
+	withT, err3 := artist.Albums().Where("name", "LIKE", "%t%").SortBy("name", true).Get(nil)
+	first, err4 := artist.Albums().Where("year", "<", 1990).OrWhere("name", "=", "Monster").SortBy("year", true).First(nil)
+	short, err5 := artist.Albums().Filter(func(a Album) bool {
+		return len(a.Name) <= 7
+	}).Get(nil)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Len(t, nineties, 3)
+	assert.Equal(t, "Monster", nineties[0].Name)
+	assert.Equal(t, "Out of Time", nineties[2].Name)
+	// LIKE is case sensitive, just like in the database.
+	assert.NoError(t, err3)
+	assert.Len(t, withT, 4)
+	assert.Equal(t, "Automatic for the People", withT[0].Name)
+	assert.NoError(t, err4)
+	assert.Equal(t, "Murmur", first.Name)
+	assert.NoError(t, err5)
+	assert.Len(t, short, 2)
+	assert.Equal(t, 0, *queries)
+	// The loaded data itself is untouched.
+	assert.Len(t, artist.Albums().MustGet(nil), 5)
+}
+
+func TestRelationWhereMatchesDatabase(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Talking Heads"},
+	})
+	Seed(db, []Album{
+		{Name: "Talking Heads: 77", Year: 1977, ArtistID: ids[0]},
+		{Name: "Fear of Music", Year: 1979, ArtistID: ids[0]},
+		{Name: "Remain in Light", Year: 1980, ArtistID: ids[0]},
+		{Name: "Speaking in Tongues", Year: 1983, ArtistID: ids[0]},
+	})
+	notLoaded := si.Query[Artist]().MustFind(db, ids[0])
+	loaded, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Execute(db, r)
+	}).First(db)
+
+	// Not loaded, so this is done with SQL.
+	fromDB, err2 := notLoaded.Albums().Where("year", "!=", 1979).Where("name", "ILIKE", "%in%").SortBy("year", false).Get(db)
+	fromMemory, err3 := loaded.Albums().Where("year", "!=", 1979).Where("name", "ILIKE", "%in%").SortBy("year", false).Get(nil)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Len(t, fromDB, 3)
+	assert.Len(t, fromMemory, 3)
+	for i := range fromDB {
+		assert.Equal(t, fromDB[i].Name, fromMemory[i].Name)
+	}
+}
+
+func TestOnlyDeleted(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	now := time.Now()
+	Seed(db, []Artist{
+		{Name: "Hüsker Dü", Model: si.Model{DeletedAt: &now}},
+		{Name: "The Replacements"},
+	})
+
+	list, err := si.Query[Artist]().OnlyDeleted().Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "Hüsker Dü", list[0].Name)
+}
+
+func TestNPlusOneDetected(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Cocteau Twins"},
+		{Name: "Dead Can Dance"},
+		{Name: "This Mortal Coil"},
+	})
+	Seed(db, []Album{
+		{Name: "Treasure", ArtistID: ids[0]},
+		{Name: "Spleen and Ideal", ArtistID: ids[1]},
+	})
+	artists := si.Query[Artist]().OrderBy("name", true).MustGet(db)
+
+	var errs []error
+	for _, artist := range artists {
+		_, err := artist.Albums().Get(db)
+		errs = append(errs, err)
+	}
+
+	// The first load is fine, the repeated ones are reported.
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	var n si.NPlusOne
+	assert.ErrorAs(t, errs[1], &n)
+	assert.Equal(t, "albums", n.Relation)
+	assert.Contains(t, n.Caller, "query_builder_test.go")
+	assert.ErrorContains(t, errs[1], "With(")
+}
+
+func TestNPlusOneSeparateResults(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Slowdive"},
+		{Name: "Ride"},
+	})
+	Seed(db, []Album{
+		{Name: "Souvlaki", ArtistID: ids[0]},
+		{Name: "Nowhere", ArtistID: ids[1]},
+	})
+
+	// The artists come from different results, so these are not siblings.
+	slowdive := si.Query[Artist]().MustFind(db, ids[0])
+	ride := si.Query[Artist]().MustFind(db, ids[1])
+	_, err := slowdive.Albums().Get(db)
+	_, err2 := ride.Albums().Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+}
+
+func TestNPlusOneWritesExempt(t *testing.T) {
+	db := DB(t)
+	SeedKeys[string](db, []Genre{
+		NewGenre("post-rock", "Post-rock"),
+		NewGenre("math-rock", "Math rock"),
+	})
+	ids := Seed(db, []Artist{
+		{Name: "Slint"},
+	})
+	Seed(db, []Album{
+		{Name: "Tweez", Year: 1989, ArtistID: ids[0]},
+		{Name: "Spiderland", Year: 1991, ArtistID: ids[0]},
+	})
+	albums := si.Query[Album]().OrderBy("year", true).MustGet(db)
+
+	// Writes to siblings are not reported, there is no batched alternative to use instead.
+	var errs []error
+	for _, album := range albums {
+		errs = append(errs, album.Genres().Attach(db, "post-rock"))
+		errs = append(errs, album.Genres().Sync(db, "post-rock", "math-rock"))
+	}
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, albums[1].Genres().MustGet(db), 2)
+}
+
+func TestNPlusOneLog(t *testing.T) {
+	var reports []si.NPlusOne
+	si.DetectNPlusOne(func(n si.NPlusOne) error {
+		reports = append(reports, n)
+		return nil
+	})
+	defer si.DetectNPlusOne(failOnNPlusOne)
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Lush"},
+		{Name: "Chapterhouse"},
+		{Name: "Swervedriver"},
+	})
+	Seed(db, []Contact{
+		{Email: "lush@4ad.com", ArtistID: ids[0]},
+	})
+	artists := si.Query[Artist]().MustGet(db)
+
+	var errs []error
+	for _, artist := range artists {
+		_, err := artist.Contact().First(db)
+		errs = append(errs, err)
+	}
+
+	// Nothing fails when the handler returns nil, but every repeat is reported.
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, reports, 2)
+	assert.Equal(t, "contact", reports[0].Relation)
+}
+
+func TestRelationInverseHasMany(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Mogwai"},
+	})
+	Seed(db, []Album{
+		{Name: "Young Team", ArtistID: ids[0]},
+		{Name: "Come On Die Young", ArtistID: ids[0]},
+	})
+	artist, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Execute(db, r)
+	}).First(db)
+	queries := CountQueries(t)
+
+	albums := artist.Albums().MustGet(nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, albums, 2)
+	for _, album := range albums {
+		// db is not needed here since the artist was set when the albums were loaded.
+		assert.True(t, album.Artist().Loaded())
+		assert.Equal(t, "Mogwai", album.Artist().MustFirst(nil).Name)
+	}
+	assert.Equal(t, 0, *queries)
+}
+
+func TestRelationInverseLazy(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Explosions in the Sky"},
+	})
+	Seed(db, []Album{
+		{Name: "The Earth Is Not a Cold Dead Place", ArtistID: ids[0]},
+	})
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+
+	albums, err := artist.Albums().Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, albums, 1)
+	assert.Equal(t, ids[0], *albums[0].Artist().MustFirst(nil).ID)
+}
+
+func TestRelationInverseOneToOne(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Godspeed You! Black Emperor"},
+	})
+	Seed(db, []Contact{
+		{Email: "info@cstrecords.com", ArtistID: ids[0]},
+	})
+
+	artist, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Contact().Execute(db, r)
+	}).First(db)
+	contact, err2 := si.Query[Contact]().With(func(m Contact, r []Contact) error {
+		return m.Artist().Execute(db, r)
+	}).First(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	// HasOne sets the BelongsTo on the contact, and the other way around.
+	assert.Equal(t, ids[0], *artist.Contact().MustFirst(nil).Artist().MustFirst(nil).ID)
+	assert.Equal(t, *contact.ID, *contact.Artist().MustFirst(nil).Contact().MustFirst(nil).ID)
+}
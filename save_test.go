@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"database/sql"
 	"testing"
 	"time"
 
@@ -16,11 +17,14 @@ func TestSaveToCreate(t *testing.T) {
 	ids := Seed(db, []Artist{
 		{Name: "Roger Waters"},
 	})
+	lastCall := time.Date(1943, time.September, 6, 5, 4, 3, 0, time.Local)
+	website := "https://rogerwaters.com"
 	c := Contact{
 		Email:          "roger@waters.com",
 		Phone:          1357924680,
-		RadioFrequency: 21.789,
-		LastCall:       time.Date(1943, time.September, 6, 5, 4, 3, 0, time.Local),
+		RadioFrequency: sql.Null[float64]{V: 21.789, Valid: true},
+		LastCall:       &lastCall,
+		Website:        &website,
 		OnSocialMedia:  true,
 		ArtistID:       ids[0],
 	}
@@ -32,7 +36,8 @@ func TestSaveToCreate(t *testing.T) {
 	assert.Equal(t, c.Email, c2.Email)
 	assert.Equal(t, c.Phone, c2.Phone)
 	assert.Equal(t, c.RadioFrequency, c2.RadioFrequency)
-	assert.Equal(t, c.LastCall, c2.LastCall.Local())
+	assert.Equal(t, *c.LastCall, c2.LastCall.Local())
+	assert.Equal(t, c.Website, c2.Website)
 	assert.Equal(t, c.OnSocialMedia, c2.OnSocialMedia)
 	assert.Equal(t, c.ArtistID, c2.ArtistID)
 }
@@ -44,41 +49,133 @@ func TestSaveToUpdate(t *testing.T) {
 	})
 	Seed(db, []Contact{
 		{
-
-			// TODO: Nullable values
-
-			Email:          "Ett brev",
-			Phone:          192837465,
-			RadioFrequency: 98.54,
-			LastCall:       time.Now(),
-			OnSocialMedia:  false,
-			ArtistID:       ids[0],
+			Email:         "Ett brev",
+			Phone:         192837465,
+			OnSocialMedia: false,
+			ArtistID:      ids[0],
 		},
 	})
 
 	email := "Det Löser sig"
 	phone := 7592836
-	radio := 73.11
+	radio := sql.Null[float64]{V: 73.11, Valid: true}
 	lastCall := time.Date(1234, 5, 6, 7, 8, 9, 0, time.Local)
 	onSM := true
 	c := si.Query[Contact]().MustFind(db)
+	seededRadio, seededLastCall, seededWebsite := c.RadioFrequency, c.LastCall, c.Website
 	c.Email = email
 	c.Phone = phone
 	c.RadioFrequency = radio
-	c.LastCall = lastCall
+	c.LastCall = &lastCall
 	c.OnSocialMedia = onSM
 	err := si.Save(db, c)
 
 	c2 := si.Query[Contact]().MustFind(db)
 
 	assert.NoError(t, err)
+	assert.False(t, seededRadio.Valid)
+	assert.Nil(t, seededLastCall)
+	assert.Nil(t, seededWebsite)
 	assert.Equal(t, email, c2.Email)
 	assert.Equal(t, phone, c2.Phone)
 	assert.Equal(t, radio, c2.RadioFrequency)
 	assert.Equal(t, lastCall, c2.LastCall.Local())
+	assert.Nil(t, c2.Website)
 	assert.Equal(t, onSM, c2.OnSocialMedia)
 }
 
+func TestSaveNullValues(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Bob Hund"},
+	})
+	lastCall := time.Now()
+	website := "https://bobhund.se"
+	Seed(db, []Contact{
+		{
+			Email:          "hund@bob.se",
+			RadioFrequency: sql.Null[float64]{V: 101.1, Valid: true},
+			LastCall:       &lastCall,
+			Website:        &website,
+			Fax:            sql.NullString{String: "08-123 45", Valid: true},
+			ArtistID:       ids[0],
+		},
+	})
+
+	c := si.Query[Contact]().MustFind(db)
+	c.RadioFrequency = sql.Null[float64]{}
+	c.LastCall = nil
+	c.Fax = sql.NullString{}
+	err := si.Save(db, c)
+	c2 := si.Query[Contact]().MustFind(db)
+
+	assert.NoError(t, err)
+	assert.False(t, c2.RadioFrequency.Valid)
+	assert.Nil(t, c2.LastCall)
+	assert.False(t, c2.Fax.Valid)
+	if assert.NotNil(t, c2.Website) {
+		assert.Equal(t, website, *c2.Website)
+	}
+}
+
+func TestSaveNullString(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Bob Hund"},
+	})
+	Seed(db, []Contact{
+		{Email: "hund@bob.se", Fax: sql.NullString{String: "08-123 45", Valid: true}, ArtistID: ids[0]},
+	})
+
+	contacts, err := si.Query[Contact]().Get(db)
+	c := si.Query[Contact]().MustFind(db)
+	c.Fax = sql.NullString{String: "08-543 21", Valid: true}
+	err2 := si.Update(db, c, []string{"fax"})
+	updated, err3 := si.Query[Contact]().Find(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, contacts, 1)
+	assert.Equal(t, sql.NullString{String: "08-123 45", Valid: true}, contacts[0].Fax)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, sql.NullString{String: "08-543 21", Valid: true}, updated.Fax)
+}
+
+func TestSetNull(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Bob Hund"},
+	})
+	website := "https://bobhund.se"
+	Seed(db, []Contact{
+		{Email: "hund@bob.se", Website: &website, ArtistID: ids[0]},
+	})
+
+	err := si.Set[Contact]().Set("website", sql.NullString{}).Do(db)
+	nulled, err2 := si.Query[Contact]().Where("website", "IS", nil).Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Len(t, nulled, 1)
+	assert.Nil(t, nulled[0].Website)
+}
+
+func TestNullIntoNonNullableField(t *testing.T) {
+	db := DB(t)
+	Seed(db, []Artist{
+		{Name: "Lars Winnerbäck", Nickname: "Winnerbäck"},
+	})
+
+	err := si.Set[Artist]().Set("nickname", nil).Do(db)
+	artist, err2 := si.Query[Artist]().First(db)
+
+	assert.NoError(t, err)
+	assert.Nil(t, artist)
+	assert.Error(t, err2)
+	// The error should point out what column could not be read.
+	assert.ErrorContains(t, err2, "nickname")
+}
+
 func TestUpdateWhenNotExists(t *testing.T) {
 	db := DB(t)
 
@@ -284,3 +381,815 @@ func TestUpdatedAtChangedOnUpdate(t *testing.T) {
 	assert.True(t, stored.UpdatedAt.After(originalUpdatedAt))
 	assert.WithinDuration(t, originalCreatedAt, stored.CreatedAt, time.Second)
 }
+
+func TestSaveJSON(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Radiohead"},
+	})
+	album := &Album{
+		Name: "OK Computer",
+		Year: 1997,
+		Details: AlbumDetails{
+			Label:   "Parlophone",
+			Formats: []string{"CD", "LP", "MC"},
+			Tracks:  12,
+		},
+		Credits: map[string][]string{
+			"producer": {"Nigel Godrich", "Radiohead"},
+		},
+		Musicians: []string{"Thom Yorke", "Jonny Greenwood", "Colin Greenwood", "Ed O'Brien", "Philip Selway"},
+		ArtistID:  ids[0],
+	}
+
+	err := si.Save(db, album)
+	stored, err2 := si.Query[Album]().Find(db, *album.ID)
+
+	album.Details.Tracks = 13
+	err3 := si.Update(db, album, []string{"details"})
+	updated, err4 := si.Query[Album]().Find(db, *album.ID)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.NoError(t, err4)
+	assert.Equal(t, album.Credits, stored.Credits)
+	assert.Equal(t, album.Musicians, stored.Musicians)
+	assert.Equal(t, "Parlophone", stored.Details.Label)
+	assert.Equal(t, []string{"CD", "LP", "MC"}, stored.Details.Formats)
+	assert.Equal(t, 12, stored.Details.Tracks)
+	assert.Equal(t, 13, updated.Details.Tracks)
+}
+
+func TestSaveArrays(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Santana", Genres: []string{"latin rock", "blues rock"}},
+		{Name: "Rob Thomas"},
+	})
+	album := &Album{
+		Name:     "Supernatural",
+		Charts:   []int64{1, 1, 3},
+		Guests:   []uuid.UUID{ids[1]},
+		ArtistID: ids[0],
+	}
+
+	err := si.Save(db, album)
+	storedAlbum, err2 := si.Query[Album]().Find(db, *album.ID)
+	storedArtist, err3 := si.Query[Artist]().Find(db, ids[0])
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, []string{"latin rock", "blues rock"}, storedArtist.Genres)
+	assert.Equal(t, []int64{1, 1, 3}, storedAlbum.Charts)
+	assert.Equal(t, []uuid.UUID{ids[1]}, storedAlbum.Guests)
+}
+
+func TestSaveCustomTypes(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Daft Punk"},
+	})
+	catalogID := CatalogID{uuid.New()}
+	album := &Album{
+		Name:      "Discovery",
+		Price:     Money(1999),
+		Length:    61*time.Minute + 50*time.Second,
+		CatalogID: &catalogID,
+		ArtistID:  ids[0],
+	}
+	contact := &Contact{
+		Email:     "robots@daftpunk.com",
+		Preferred: ContactMethodRadio,
+		ArtistID:  ids[0],
+	}
+
+	err := si.Save(db, album)
+	err2 := si.Save(db, contact)
+	storedAlbum := si.Query[Album]().MustFind(db, *album.ID)
+	storedContact := si.Query[Contact]().MustFind(db, *contact.ID)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, "19.99", storedAlbum.Price.String())
+	assert.Equal(t, album.Length, storedAlbum.Length)
+	assert.Equal(t, catalogID, *storedAlbum.CatalogID)
+	assert.Equal(t, ContactMethodRadio, storedContact.Preferred)
+}
+
+func TestSaveNegativeMoney(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Daft Punk"},
+	})
+	album := &Album{Name: "Refund", Price: Money(-150), ArtistID: ids[0]}
+
+	err := si.Save(db, album)
+	stored := si.Query[Album]().MustFind(db, *album.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Money(-150), stored.Price)
+	assert.Equal(t, "-1.50", stored.Price.String())
+	assert.Equal(t, "-0.50", Money(-50).String())
+}
+
+func TestSaveInvalidCustomType(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Thomas Bangalter"},
+	})
+
+	// The error from driver.Valuer is returned, and nothing is written.
+	err := si.Save(db, &Contact{
+		Email:     "thomas@daftpunk.com",
+		Preferred: ContactMethod("pigeon"),
+		ArtistID:  ids[0],
+	})
+	contacts, err2 := si.Query[Contact]().Get(db)
+
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "pigeon")
+	assert.NoError(t, err2)
+	assert.Len(t, contacts, 0)
+}
+
+func TestSaveColumnTag(t *testing.T) {
+	db := DB(t)
+	homepage := "https://www.thecure.com"
+	artist := &Artist{
+		Name:     "The Cure",
+		Homepage: &homepage,
+	}
+
+	err := si.Save(db, artist)
+	stored, err2 := si.Query[Artist]().Where("homepage_url", "=", homepage).Find(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, homepage, *stored.Homepage)
+}
+
+func TestSaveIgnoredField(t *testing.T) {
+	db := DB(t)
+	artist := &Artist{
+		Name:       "Siouxsie and the Banshees",
+		Popularity: 87,
+	}
+
+	// There is no popularity column, so this would fail if it was written.
+	err := si.Save(db, artist)
+	stored, err2 := si.Query[Artist]().Find(db, *artist.ID)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, 0, stored.Popularity)
+}
+
+func TestSaveReadonlyField(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "New Order"},
+	})
+	album := &Album{
+		Name:     "Power, Corruption & Lies",
+		Year:     1983,
+		Decade:   1700, // Ignored, since decade is generated by the database.
+		ArtistID: ids[0],
+	}
+
+	err := si.Save(db, album)
+	stored := si.Query[Album]().MustFind(db, *album.ID)
+
+	album.Year = 1993
+	err2 := si.Update(db, album, []string{"year", "decade"})
+	updated := si.Query[Album]().MustFind(db, *album.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1980, stored.Decade)
+	assert.NoError(t, err2)
+	assert.Equal(t, 1990, updated.Decade)
+}
+
+func TestSaveEmbeddedStruct(t *testing.T) {
+	db := DB(t)
+	artist := &Artist{
+		Auditing: Auditing{CreatedBy: "alice"},
+		Name:     "Fleetwood Mac",
+	}
+
+	err := si.Save(db, artist)
+	artist.UpdatedBy = "bob"
+	err2 := si.Save(db, artist)
+	stored, err3 := si.Query[Artist]().Where("created_by", "=", "alice").Find(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, "alice", stored.CreatedBy)
+	assert.Equal(t, "bob", stored.UpdatedBy)
+}
+
+func TestSaveEmbeddedPrefixedStruct(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "The Smiths"},
+	})
+	contact := &Contact{
+		Email:    "info@thesmiths.co.uk",
+		Address:  Address{Street: "Kings Road 384", City: "London"},
+		ArtistID: ids[0],
+	}
+
+	err := si.Save(db, contact)
+	contact.Address.City = "Manchester"
+	err2 := si.Update(db, contact, []string{"address_city"})
+	stored, err3 := si.Query[Contact]().Where("address_city", "=", "Manchester").Find(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, Address{Street: "Kings Road 384", City: "Manchester"}, stored.Address)
+}
+
+func TestSerialPrimaryKey(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	label := &Label{Name: "Warp"}
+
+	err := si.Save(db, label)
+	label.Name = "Warp Records"
+	err2 := si.Save(db, label)
+	stored, err3 := si.Query[Label]().Find(db, *label.ID)
+	err4 := si.Delete[Label](db, *label.ID)
+	deleted, err5 := si.Query[Label]().First(db)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, label.ID)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, *label.ID, *stored.ID)
+	assert.Equal(t, "Warp Records", stored.Name)
+	assert.NoError(t, err4)
+	assert.NoError(t, err5)
+	assert.Nil(t, deleted)
+}
+
+func TestNaturalPrimaryKey(t *testing.T) {
+	db := DB(t)
+	genre := NewGenre("idm", "Intelligent dance music")
+
+	// The key is always set, so Insert is used to create the row, and Save updates it.
+	err := si.Insert(db, &genre)
+	genre.Name = "IDM"
+	err2 := si.Save(db, &genre)
+	stored, err3 := si.Query[Genre]().Find(db, "idm")
+	err4 := si.DeleteHard[Genre](db, "idm")
+	genres, err5 := si.Query[Genre]().WithDeleted().Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, "IDM", stored.Name)
+	assert.NoError(t, err4)
+	assert.NoError(t, err5)
+	assert.Len(t, genres, 0)
+}
+
+func TestCompositePrimaryKey(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Aphex Twin"},
+		{Name: "Boards of Canada"},
+	})
+	labelIDs := SeedKeys[int64](db, []Label{
+		{Name: "Warp"},
+	})
+	keys := SeedKeys[ArtistLabelKey](db, []ArtistLabel{
+		{ModelOf: si.ModelOf[ArtistLabelKey]{ID: &ArtistLabelKey{ArtistID: ids[0], LabelID: labelIDs[0]}}, Role: "artist"},
+		{ModelOf: si.ModelOf[ArtistLabelKey]{ID: &ArtistLabelKey{ArtistID: ids[1], LabelID: labelIDs[0]}}, Role: "artist"},
+	})
+
+	key := keys[0]
+	artistLabel, err := si.Query[ArtistLabel]().Find(db, key)
+	artistLabel.Role = "producer"
+	err2 := si.Update(db, artistLabel, []string{"role"})
+	updated, err3 := si.Query[ArtistLabel]().Find(db, key)
+	other, err4 := si.Query[ArtistLabel]().Find(db, keys[1])
+	err5 := si.DeleteHard[ArtistLabel](db, key)
+	remaining, err6 := si.Query[ArtistLabel]().Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, "producer", updated.Role)
+	assert.NoError(t, err4)
+	assert.Equal(t, "artist", other.Role)
+	assert.NoError(t, err5)
+	assert.NoError(t, err6)
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, ids[1], remaining[0].ID.ArtistID)
+}
+
+func TestRenamedTimestamps(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Kent"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Isola", ArtistID: ids[0]},
+	})
+	before := time.Now()
+	track := &Track{Name: "747", Number: 1, AlbumID: albumIDs[0]}
+
+	err := si.Save(db, track)
+	assert.NoError(t, err)
+	originalUpdatedAt := *track.UpdatedAt
+
+	// Ensure the clock advances so the new modified_at is strictly later.
+	time.Sleep(10 * time.Millisecond)
+
+	track.Name = "Things She Said"
+	err2 := si.Save(db, track)
+	stored, err3 := si.Query[Track]().Where("inserted_at", ">=", before).Find(db)
+
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.WithinDuration(t, track.CreatedAt, stored.CreatedAt, time.Second)
+	if assert.NotNil(t, stored.UpdatedAt) {
+		assert.True(t, stored.UpdatedAt.After(originalUpdatedAt))
+	}
+	assert.Equal(t, "Things She Said", stored.Name)
+}
+
+func TestSoftDeletePerModel(t *testing.T) {
+	si.UseDeletedAt(false)
+	defer si.UseDeletedAt(true)
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Kent"},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Hagnesta Hill", ArtistID: ids[0]},
+	})
+	trackIDs := Seed(db, []Track{
+		{Name: "Kevlarsjäl", Number: 1, AlbumID: albumIDs[0]},
+		{Name: "Musik non stop", Number: 2, AlbumID: albumIDs[0]},
+	})
+
+	// Tracks declare archived_at, so they are soft deleted even though it's turned off globally.
+	err := si.Delete[Track](db, trackIDs[0])
+	tracks, err2 := si.Query[Track]().Get(db)
+	archived, err3 := si.Query[Track]().WithDeleted().Where("archived_at", "IS NOT", nil).Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Len(t, tracks, 1)
+	assert.Equal(t, trackIDs[1], *tracks[0].ID)
+	assert.Len(t, archived, 1)
+	assert.NotNil(t, archived[0].DeletedAt)
+}
+
+func TestWithoutTimestamps(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	genre := NewGenre("shoegaze", "Shoegaze")
+
+	// genres has no timestamp columns, so none are written, and delete is always hard.
+	err := si.Insert(db, &genre)
+	err2 := si.Save(db, &genre)
+	stored, err3 := si.Query[Genre]().Find(db, "shoegaze")
+	err4 := si.Delete[Genre](db, "shoegaze")
+	genres, err5 := si.Query[Genre]().WithDeleted().Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.True(t, stored.CreatedAt.IsZero())
+	assert.Nil(t, stored.UpdatedAt)
+	assert.NoError(t, err4)
+	assert.NoError(t, err5)
+	assert.Len(t, genres, 0)
+}
+
+func TestAttachDetachSync(t *testing.T) {
+	db := DB(t)
+	SeedKeys[string](db, []Genre{
+		NewGenre("punk", ""),
+		NewGenre("ska", ""),
+		NewGenre("reggae", ""),
+	})
+	ids := Seed(db, []Artist{
+		{Name: "The Clash"},
+	})
+	Seed(db, []Album{
+		{Name: "London Calling", ArtistID: ids[0]},
+	})
+	album := si.Query[Album]().MustFind(db)
+
+	err := album.Genres().Attach(db, "punk")
+	err2 := album.Genres().Attach(db, "ska")
+	attached := album.Genres().MustGet(db)
+	err3 := album.Genres().Detach(db, "punk")
+	detached := album.Genres().MustGet(db)
+	// Sync adds reggae and punk, and keeps ska.
+	err4 := album.Genres().Sync(db, "reggae", "punk", "ska")
+	synced := album.Genres().MustGet(db)
+	// Sync with a subset removes the rest.
+	err5 := album.Genres().Sync(db, "reggae")
+	resynced := album.Genres().MustGet(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Len(t, attached, 2)
+	assert.NoError(t, err3)
+	assert.Len(t, detached, 1)
+	assert.Equal(t, "ska", *detached[0].ID)
+	assert.NoError(t, err4)
+	assert.Len(t, synced, 3)
+	assert.NoError(t, err5)
+	assert.Len(t, resynced, 1)
+	assert.Equal(t, "reggae", *resynced[0].ID)
+	// The genres themselves are never removed.
+	assert.Len(t, si.Query[Genre]().MustGet(db), 3)
+}
+
+func TestRelationCreate(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Blur"},
+		{Name: "Oasis"},
+	})
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+	album := Album{Name: "Parklife"}
+
+	_, err := artist.Albums().Create(db, &album)
+	stored, err2 := si.Query[Album]().Find(db, *album.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ids[0], album.ArtistID)
+	assert.NoError(t, err2)
+	assert.Equal(t, ids[0], stored.ArtistID)
+}
+
+func TestRelationCreateLoaded(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Pulp"},
+	})
+	Seed(db, []Album{
+		{Name: "His 'n' Hers", ArtistID: ids[0]},
+	})
+	artist, err := si.Query[Artist]().With(func(m Artist, r []Artist) error {
+		return m.Albums().Execute(db, r)
+	}).First(db)
+
+	// The relation holds a copy of the artist, so the updated artist is returned.
+	updated, err2 := artist.Albums().Create(db, &Album{Name: "Different Class"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	// The new album is added to the already loaded albums.
+	assert.Len(t, updated.Albums().MustGet(nil), 2)
+}
+
+func TestRelationSaveMany(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Suede"},
+	})
+	Seed(db, []Album{
+		{Name: "Suede", Year: 1992, ArtistID: ids[0]},
+	})
+	artist := si.Query[Artist]().MustFind(db, ids[0])
+	existing := si.Query[Album]().MustFind(db)
+	existing.Year = 1993
+	newAlbum := &Album{Name: "Dog Man Star", Year: 1994}
+
+	// Existing albums are updated, and new ones are created.
+	_, err := artist.Albums().SaveMany(db, existing, newAlbum)
+	albums, err2 := artist.Albums().SortBy("year", true).Get(db)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, newAlbum.ID)
+	assert.NoError(t, err2)
+	assert.Len(t, albums, 2)
+	assert.Equal(t, 1993, albums[0].Year)
+	assert.Equal(t, "Dog Man Star", albums[1].Name)
+}
+
+func TestRelationAssociateAndDissociate(t *testing.T) {
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Elastica"},
+	})
+	Seed(db, []Album{
+		{Name: "Elastica", ArtistID: ids[0]},
+	})
+	labelIDs := SeedKeys[int64](db, []Label{
+		{Name: "Deceptive"},
+	})
+	label := si.Query[Label]().MustFind(db, labelIDs[0])
+	album := si.Query[Album]().MustFind(db)
+
+	// The relation holds a copy of the album, so the updated album is returned.
+	associated, err := album.Label().Associate(db, label)
+	stored := si.Query[Album]().MustFind(db)
+	dissociated, err2 := stored.Label().Dissociate(db)
+	storedAgain := si.Query[Album]().MustFind(db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, labelIDs[0], *associated.LabelID)
+	assert.Equal(t, labelIDs[0], *associated.Label().MustFirst(nil).ID)
+	assert.Equal(t, labelIDs[0], *stored.LabelID)
+	assert.NoError(t, err2)
+	assert.Nil(t, dissociated.LabelID)
+	assert.Nil(t, storedAgain.LabelID)
+}
+
+func TestDeleteCascade(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	ids := Seed(db, []Artist{
+		{Name: "The Stone Roses"},
+	})
+	Seed(db, []Contact{
+		{Email: "ian@stoneroses.com", ArtistID: ids[0]},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "The Stone Roses", ArtistID: ids[0]},
+		{Name: "Second Coming", ArtistID: ids[0]},
+		{Name: "Garage Flower", ArtistID: ids[0], Model: si.Model{DeletedAt: &yesterday}},
+	})
+	trackIDs := Seed(db, []Track{
+		{Name: "I Wanna Be Adored", Number: 1, AlbumID: albumIDs[0]},
+		{Name: "Fools Gold", Number: 11, AlbumID: albumIDs[0]},
+	})
+	Seed(db, []TrackCredit{
+		{Name: "John Leckie", Role: "producer", TrackID: trackIDs[0]},
+	})
+
+	err := si.Delete[Artist](db, ids[0])
+
+	assert.NoError(t, err)
+	assert.Len(t, si.Query[Artist]().MustGet(db), 0)
+	assert.Len(t, si.Query[Contact]().MustGet(db), 0)
+	assert.Len(t, si.Query[Album]().MustGet(db), 0)
+	assert.Len(t, si.Query[Track]().MustGet(db), 0)
+	// Soft deleted rows are kept, but credits are always hard deleted.
+	assert.Len(t, si.Query[Contact]().WithDeleted().MustGet(db), 1)
+	assert.Len(t, si.Query[Album]().WithDeleted().MustGet(db), 3)
+	assert.Len(t, si.Query[Track]().WithDeleted().MustGet(db), 2)
+	assert.Len(t, si.Query[TrackCredit]().WithDeleted().MustGet(db), 0)
+}
+
+func TestDeleteHardCascade(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	ids := Seed(db, []Artist{
+		{Name: "Inspiral Carpets"},
+	})
+	Seed(db, []Contact{
+		{Email: "clint@inspiralcarpets.com", ArtistID: ids[0]},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Life", ArtistID: ids[0]},
+		{Name: "The Beast Inside", ArtistID: ids[0], Model: si.Model{DeletedAt: &yesterday}},
+	})
+	trackIDs := Seed(db, []Track{
+		{Name: "This Is How It Feels", Number: 3, AlbumID: albumIDs[0]},
+	})
+	Seed(db, []TrackCredit{
+		{Name: "Nick Garside", Role: "producer", TrackID: trackIDs[0]},
+	})
+
+	err := si.DeleteHard[Artist](db, ids[0])
+
+	assert.NoError(t, err)
+	// The soft deleting relations are hard deleted as well, including rows that were already soft deleted.
+	assert.Len(t, si.Query[Artist]().WithDeleted().MustGet(db), 0)
+	assert.Len(t, si.Query[Contact]().WithDeleted().MustGet(db), 0)
+	assert.Len(t, si.Query[Album]().WithDeleted().MustGet(db), 0)
+	assert.Len(t, si.Query[Track]().WithDeleted().MustGet(db), 0)
+	assert.Len(t, si.Query[TrackCredit]().WithDeleted().MustGet(db), 0)
+}
+
+func TestDeleteCascadeRollback(t *testing.T) {
+	si.UseDeletedAt(true)
+	// The test adds a constraint, so it needs the raw transaction that DB(t) hides.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to create database transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		err := tx.Rollback()
+		if err != nil {
+			t.Fatalf("Failed to rollback database transaction: %v", err)
+		}
+	})
+	// Album.Genres has no OnDelete, so with this constraint the albums can not be hard deleted.
+	_, err = tx.Exec("ALTER TABLE album_genres ADD FOREIGN KEY (album_id) REFERENCES albums (id)")
+	if err != nil {
+		t.Fatalf("Failed to add foreign key: %v", err)
+	}
+	db := si.WrapDB(tx)
+	SeedKeys[string](db, []Genre{
+		NewGenre("madchester", "Madchester"),
+	})
+	ids := Seed(db, []Artist{
+		{Name: "The Charlatans"},
+	})
+	Seed(db, []Contact{
+		{Email: "tim@thecharlatans.com", ArtistID: ids[0]},
+	})
+	Seed(db, []Album{
+		{Name: "Some Friendly", ArtistID: ids[0]},
+	})
+	album := si.Query[Album]().MustFind(db)
+	assert.NoError(t, album.Genres().Attach(db, "madchester"))
+
+	// Within a transaction, si runs the cascade in a SAVEPOINT and rolls back to it when a step fails,
+	// so the transaction can still be used afterwards.
+	err2 := si.DeleteHard[Artist](db, ids[0])
+	artists, err3 := si.Query[Artist]().Get(db)
+	contacts, err4 := si.Query[Contact]().Get(db)
+	albums, err5 := si.Query[Album]().Get(db)
+	genres, err6 := album.Genres().Get(db)
+
+	assert.Error(t, err2)
+	// Nothing of the cascade is kept when it fails part-way through.
+	assert.NoError(t, err3)
+	assert.Len(t, artists, 1)
+	assert.NoError(t, err4)
+	assert.Len(t, contacts, 1)
+	assert.NoError(t, err5)
+	assert.Len(t, albums, 1)
+	assert.NoError(t, err6)
+	assert.Len(t, genres, 1)
+}
+
+func TestRestoreCascade(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	ids := Seed(db, []Artist{
+		{Name: "Happy Mondays"},
+	})
+	Seed(db, []Contact{
+		{Email: "shaun@happymondays.com", ArtistID: ids[0]},
+	})
+	albumIDs := Seed(db, []Album{
+		{Name: "Pills 'n' Thrills and Bellyaches", ArtistID: ids[0]},
+		{Name: "Yes Please!", ArtistID: ids[0], Model: si.Model{DeletedAt: &yesterday}},
+	})
+	Seed(db, []Track{
+		{Name: "Kinky Afro", Number: 1, AlbumID: albumIDs[0]},
+	})
+	assert.NoError(t, si.Delete[Artist](db, ids[0]))
+
+	err := si.Restore[Artist](db, ids[0])
+	albums, err2 := si.Query[Album]().Get(db)
+
+	assert.NoError(t, err)
+	assert.Len(t, si.Query[Artist]().MustGet(db), 1)
+	assert.Len(t, si.Query[Contact]().MustGet(db), 1)
+	assert.Len(t, si.Query[Track]().MustGet(db), 1)
+	// Only what was deleted by the cascade is restored.
+	assert.NoError(t, err2)
+	assert.Len(t, albums, 1)
+	assert.Equal(t, albumIDs[0], *albums[0].ID)
+}
+
+func TestDeleteCascadeNullify(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Primal Scream"},
+	})
+	labelIDs := SeedKeys[int64](db, []Label{
+		{Name: "Creation"},
+	})
+	Seed(db, []Album{
+		{Name: "Screamadelica", LabelID: &labelIDs[0], ArtistID: ids[0]},
+	})
+
+	err := si.Delete[Label](db, labelIDs[0])
+	softDeleted := si.Query[Album]().MustFind(db)
+	err2 := si.DeleteHard[Label](db, labelIDs[0])
+	hardDeleted := si.Query[Album]().MustFind(db)
+
+	assert.NoError(t, err)
+	// The soft deleted label can be restored, so the album keeps it.
+	if assert.NotNil(t, softDeleted.LabelID) {
+		assert.Equal(t, labelIDs[0], *softDeleted.LabelID)
+	}
+	assert.NoError(t, err2)
+	assert.Nil(t, hardDeleted.LabelID)
+}
+
+func TestDeleteCascadeRestrict(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "My Bloody Valentine"},
+	})
+	labelIDs := SeedKeys[int64](db, []Label{
+		{Name: "Creation"},
+	})
+	Seed(db, []Album{
+		{Name: "Loveless", LabelID: &labelIDs[0], ArtistID: ids[0]},
+	})
+	assert.NoError(t, si.Query[Artist]().MustFind(db, ids[0]).Labels().Attach(db, labelIDs[0]))
+
+	err := si.DeleteHard[Label](db, labelIDs[0])
+	album := si.Query[Album]().MustFind(db)
+
+	assert.Error(t, err)
+	assert.Len(t, si.Query[Label]().MustGet(db), 1)
+	// Nothing is changed when the delete is restricted, not even the nullified albums.
+	assert.Equal(t, labelIDs[0], *album.LabelID)
+}
+
+func TestRestore(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	ids := Seed(db, []Artist{
+		{Name: "Pixies"},
+	})
+	assert.NoError(t, si.Delete[Artist](db, ids[0]))
+	before := time.Now()
+
+	err := si.Restore[Artist](db, ids[0])
+	artist, err2 := si.Query[Artist]().Find(db, ids[0])
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Nil(t, artist.DeletedAt)
+	assert.NotNil(t, artist.UpdatedAt)
+	assert.False(t, artist.UpdatedAt.Before(before))
+}
+
+func TestRestoreWhenNotExists(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+
+	err := si.Restore[Artist](db, uuid.New())
+
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, si.ResourceNotFoundError{})
+}
+
+func TestRestoreQuery(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	now := time.Now()
+	Seed(db, []Artist{
+		{Name: "Sonic Youth", Model: si.Model{DeletedAt: &now}},
+		{Name: "Swans", Model: si.Model{DeletedAt: &now}},
+		{Name: "Slint", Model: si.Model{DeletedAt: &now}},
+		{Name: "Shellac"},
+	})
+	before := time.Now()
+
+	err := si.Query[Artist]().OnlyDeleted().Where("name", "!=", "Slint").Restore(db)
+	artists, err2 := si.Query[Artist]().OrderBy("name", true).Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Len(t, artists, 3)
+	assert.Equal(t, "Shellac", artists[0].Name)
+	assert.Equal(t, "Sonic Youth", artists[1].Name)
+	assert.Equal(t, "Swans", artists[2].Name)
+	for _, artist := range artists[1:] {
+		if assert.NotNil(t, artist.UpdatedAt) {
+			assert.False(t, artist.UpdatedAt.Before(before))
+		}
+	}
+}
+
+func TestPurge(t *testing.T) {
+	si.UseDeletedAt(true)
+	db := DB(t)
+	lastYear := time.Now().AddDate(-1, 0, 0)
+	yesterday := time.Now().AddDate(0, 0, -1)
+	Seed(db, []Artist{
+		{Name: "Minutemen", Model: si.Model{DeletedAt: &lastYear}},
+		{Name: "fIREHOSE", Model: si.Model{DeletedAt: &yesterday}},
+		{Name: "Mike Watt"},
+	})
+
+	err := si.Purge[Artist](db, time.Now().AddDate(0, -1, 0))
+	var names []string
+	_, err2 := si.Query[Artist]().WithDeleted().Select([]string{"name"}, func(scan func(...any)) {
+		var name string
+		scan(&name)
+		names = append(names, name)
+	}).Get(db)
+
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.ElementsMatch(t, []string{"fIREHOSE", "Mike Watt"}, names)
+}